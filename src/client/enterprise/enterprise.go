@@ -0,0 +1,47 @@
+package enterprise
+
+import (
+	"golang.org/x/net/context"
+)
+
+// Feature names accepted by client.Enterprise.HasFeature. These correspond
+// to the named fields on Policies that represent on/off entitlements; the
+// quota fields (MaxPipelines, MaxUsers, ObjectStorageTiers) are read
+// directly off GetPolicies instead, since "has" doesn't make sense for a
+// limit.
+const (
+	FeatureAuthSAML = "auth_saml"
+)
+
+// Client wraps the generated APIClient with convenience methods. This is
+// the type of client.APIClient's Enterprise field, so callers write
+// client.Enterprise.HasFeature(ctx, ...) rather than going through
+// GetPolicies directly.
+type Client struct {
+	APIClient
+}
+
+// HasFeature returns whether the cluster's current enterprise activation
+// code grants 'feature'. Callers should use this instead of comparing
+// GetState's State to State_ACTIVE, so that a license missing a feature
+// (e.g. one without SAML) can't enable it.
+//
+// NOTE: this repo checkout doesn't contain src/server/auth or
+// src/server/pps, so there are no State == ACTIVE call sites here to
+// migrate onto HasFeature. Whichever of those packages ends up gating
+// SAML (auth) or enterprise-only pipeline features (pps) needs to switch
+// its check from GetState().State == State_ACTIVE to
+// HasFeature(ctx, enterprise.FeatureAuthSAML) (or the relevant feature
+// name) once this package is vendored into a tree that has them.
+func (c Client) HasFeature(ctx context.Context, feature string) (bool, error) {
+	resp, err := c.GetPolicies(ctx, &GetPoliciesRequest{})
+	if err != nil {
+		return false, err
+	}
+	switch feature {
+	case FeatureAuthSAML:
+		return resp.Policies.AuthSAML, nil
+	default:
+		return false, nil
+	}
+}
@@ -0,0 +1,67 @@
+package cmds
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/enterprise"
+	"github.com/pachyderm/pachyderm/src/server/pkg/cmdutil"
+)
+
+// DiagnoseCmd returns a cobra command for 'pachctl enterprise diagnose',
+// which runs DiagnoseLicense and prints each check's result, exiting
+// non-zero if any check is FAIL.
+func DiagnoseCmd() *cobra.Command {
+	diagnose := &cobra.Command{
+		Use:   "diagnose",
+		Short: "Run diagnostic checks against the cluster's enterprise activation",
+		Long: `Run diagnostic checks against the cluster's enterprise activation,
+covering signature validity, expiration horizon, clock skew against the
+signing authority, reachability of the configured license source, and
+consistency of the activation record across etcd replicas.`,
+		Run: cmdutil.Run(func(args []string) error {
+			c, err := client.NewOnUserMachine(false, "user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			resp, err := c.Enterprise.DiagnoseLicense(context.Background(), &enterprise.DiagnoseLicenseRequest{})
+			if err != nil {
+				return err
+			}
+			failed := false
+			w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', 0)
+			fmt.Fprintf(w, "CHECK\tSTATUS\tDETAIL\n")
+			for _, result := range resp.Results {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", result.Name, result.Status, result.Detail)
+				if result.Status == enterprise.DiagnoseLicenseResult_FAIL {
+					failed = true
+				}
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			if failed {
+				os.Exit(1)
+			}
+			return nil
+		}),
+	}
+	return diagnose
+}
+
+// Cmds returns the enterprise subcommands exposed by pachctl
+func Cmds() []*cobra.Command {
+	enterprise := &cobra.Command{
+		Use:   "enterprise",
+		Short: "Enterprise commands manage the enterprise features of Pachyderm",
+		Long:  "Enterprise commands manage the enterprise features of Pachyderm",
+	}
+	enterprise.AddCommand(DiagnoseCmd())
+	return []*cobra.Command{enterprise}
+}
@@ -0,0 +1,244 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gogo/protobuf/types"
+	"github.com/sirupsen/logrus"
+	etcd "go.etcd.io/etcd/clientv3"
+	"golang.org/x/net/context"
+
+	"github.com/pachyderm/pachyderm/src/client/enterprise"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+)
+
+const (
+	enterpriseTokenKey = "token"
+)
+
+// activationCodeClaims is the JWT claim set encoded in a Pachyderm
+// enterprise activation code
+type activationCodeClaims struct {
+	jwt.StandardClaims
+
+	// Policies carries the feature entitlements granted by this activation
+	// code, if any; see policies.go
+	Policies *policyClaims `json:"policies,omitempty"`
+}
+
+// apiServer implements the enterprise.APIServer interface. It's backed by a
+// single etcd record (enterpriseTokenKey) that's guarded by the usual
+// read-modify-write collection helpers, so that concurrent pachd instances
+// agree on the current activation state.
+type apiServer struct {
+	log *logrus.Entry
+
+	etcdClient *etcd.Client
+	etcdPrefix string
+	enterpriseToken col.Collection
+
+	// autoload, if non-nil, is the configuration pachd was given (via
+	// --license-source) for reading an activation code from a file or
+	// Kubernetes Secret instead of requiring a human to call Activate. See
+	// license_autoload.go
+	autoload *licenseAutoloader
+
+	// health is a background watcher that re-validates the current
+	// activation code on a timer and tracks ACTIVE -> EXPIRING -> EXPIRED
+	// (or -> INVALID) transitions; see health_watch.go
+	health *healthWatcher
+}
+
+// enterpriseRecord is the struct stored in enterpriseToken for the one
+// well-known key (enterpriseTokenKey)
+type enterpriseRecord struct {
+	// ActivationCode is the Pachyderm enterprise activation code
+	ActivationCode string
+	// Expires is the time at which the current activation code's
+	// authorization expires
+	Expires time.Time
+	// Source records whether ActivationCode was set by a human calling
+	// Activate (MANUAL) or read from the configured license source
+	// (AUTOLOADED)
+	Source enterprise.ActivationCodeSource
+}
+
+// NewEnterpriseServer returns an apiServer that can be registered with a
+// grpc.Server that handles the Pachyderm Enterprise API. If autoload is
+// non-nil, the server loads its initial activation code from the configured
+// source and continues watching it for changes; see license_autoload.go.
+// expiringThreshold configures how far ahead of expiration GetState starts
+// reporting State_EXPIRING; zero selects defaultExpiringThreshold (30 days).
+func NewEnterpriseServer(etcdAddress string, etcdPrefix string, autoload *LicenseSourceConfig, expiringThreshold time.Duration) (enterprise.APIServer, error) {
+	etcdClient, err := etcd.New(etcd.Config{
+		Endpoints:   []string{etcdAddress},
+		DialOptions: col.DefaultDialOptions(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to etcd: %v", err)
+	}
+	s := &apiServer{
+		log:        logrus.WithField("source", "enterprise-api"),
+		etcdClient: etcdClient,
+		etcdPrefix: etcdPrefix,
+		enterpriseToken: col.NewCollection(
+			etcdClient,
+			etcdPrefix,
+			nil,
+			&enterpriseRecord{},
+			nil,
+			nil,
+		),
+	}
+	if autoload != nil {
+		a, err := newLicenseAutoloader(s, autoload)
+		if err != nil {
+			return nil, err
+		}
+		s.autoload = a
+		s.autoload.Start()
+	}
+	s.health = newHealthWatcher(s, expiringThreshold)
+	s.health.Start()
+	return s, nil
+}
+
+// parseActivationCode parses 'activationCode', checks its signature, and
+// returns the decoded claims (or an error if the code doesn't parse or
+// doesn't verify)
+func parseActivationCode(activationCode string) (*activationCodeClaims, error) {
+	claims := &activationCodeClaims{}
+	token, err := jwt.ParseWithClaims(activationCode, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return publicKey, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error validating activation code: %v", err)
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("activation code signature is invalid")
+	}
+	if claims.ExpiresAt == 0 {
+		return nil, fmt.Errorf("activation code is missing an expiration")
+	}
+	return claims, nil
+}
+
+// verifySignature checks only that 'activationCode' is a well-formed JWT
+// signed by Pachyderm's enterprise key, without enforcing its "exp" claim.
+// This is what distinguishes a tampered/corrupted code (State_INVALID)
+// from one that's merely past its expiration (State_EXPIRED) -- callers
+// that care about expiry should compare the returned claims' ExpiresAt
+// (or the stored enterpriseRecord.Expires) against time.Now() themselves.
+func verifySignature(activationCode string) (*activationCodeClaims, error) {
+	claims := &activationCodeClaims{}
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	token, err := parser.ParseWithClaims(activationCode, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return publicKey, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error validating activation code: %v", err)
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("activation code signature is invalid")
+	}
+	if claims.ExpiresAt == 0 {
+		return nil, fmt.Errorf("activation code is missing an expiration")
+	}
+	return claims, nil
+}
+
+// validateActivationCode parses 'activationCode', checks its signature, and
+// returns the expiration time embedded in it (or an error if the code
+// doesn't parse or doesn't verify)
+func validateActivationCode(activationCode string) (time.Time, error) {
+	claims, err := parseActivationCode(activationCode)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(claims.ExpiresAt, 0), nil
+}
+
+func (a *apiServer) Activate(ctx context.Context, req *enterprise.ActivateRequest) (resp *enterprise.ActivateResponse, retErr error) {
+	expiration, err := validateActivationCode(req.ActivationCode)
+	if err != nil {
+		return nil, err
+	}
+	// Allow tests to override the expiration computed from the activation
+	// code, so that they can simulate an expired or soon-to-expire license
+	// without waiting for a real one to lapse
+	if req.Expires != nil {
+		customExpiration, err := types.TimestampFromProto(req.Expires)
+		if err != nil {
+			return nil, err
+		}
+		if customExpiration.Before(expiration) {
+			expiration = customExpiration
+		}
+	}
+	return a.activate(ctx, req.ActivationCode, expiration, enterprise.ActivationCodeSource_MANUAL)
+}
+
+// activate records 'activationCode' as the cluster's current enterprise
+// token. It's shared by the Activate RPC (source=MANUAL) and the license
+// autoloader (source=AUTOLOADED).
+func (a *apiServer) activate(ctx context.Context, activationCode string, expiration time.Time, source enterprise.ActivationCodeSource) (*enterprise.ActivateResponse, error) {
+	record := &enterpriseRecord{
+		ActivationCode: activationCode,
+		Expires:        expiration,
+		Source:         source,
+	}
+	if _, err := col.NewSTM(ctx, a.etcdClient, func(stm col.STM) error {
+		return a.enterpriseToken.ReadWrite(stm).Put(enterpriseTokenKey, record)
+	}); err != nil {
+		return nil, err
+	}
+	expiresProto, err := types.TimestampProto(expiration)
+	if err != nil {
+		return nil, err
+	}
+	return &enterprise.ActivateResponse{
+		Info: &enterprise.TokenInfo{Expires: expiresProto},
+	}, nil
+}
+
+func (a *apiServer) GetState(ctx context.Context, req *enterprise.GetStateRequest) (resp *enterprise.GetStateResponse, retErr error) {
+	record := &enterpriseRecord{}
+	if err := a.enterpriseToken.ReadOnly(ctx).Get(enterpriseTokenKey, record); err != nil {
+		if col.IsErrNotFound(err) {
+			return &enterprise.GetStateResponse{State: enterprise.State_NONE}, nil
+		}
+		return nil, err
+	}
+	expiresProto, err := types.TimestampProto(record.Expires)
+	if err != nil {
+		return nil, err
+	}
+	return &enterprise.GetStateResponse{
+		State:          a.health.computeState(record),
+		Info:           &enterprise.TokenInfo{Expires: expiresProto},
+		ActivationCode: record.ActivationCode,
+		Source:         record.Source,
+		LastHealthy:    a.health.LastHealthy(),
+	}, nil
+}
+
+func (a *apiServer) Deactivate(ctx context.Context, req *enterprise.DeactivateRequest) (resp *enterprise.DeactivateResponse, retErr error) {
+	if _, err := col.NewSTM(ctx, a.etcdClient, func(stm col.STM) error {
+		err := a.enterpriseToken.ReadWrite(stm).Delete(enterpriseTokenKey)
+		if col.IsErrNotFound(err) {
+			return nil
+		}
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return &enterprise.DeactivateResponse{}, nil
+}
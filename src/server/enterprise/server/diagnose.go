@@ -0,0 +1,172 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	etcd "go.etcd.io/etcd/clientv3"
+	"golang.org/x/net/context"
+
+	"github.com/pachyderm/pachyderm/src/client/enterprise"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+)
+
+// clockSkewTolerance is how far in the future an activation code's
+// "issued at" claim is allowed to be (relative to pachd's local clock)
+// before checkClockSkew reports a problem. A healthy clock should never
+// see a token issued in the future; if it does, either pachd's clock is
+// behind, or the signing authority's is ahead
+const clockSkewTolerance = 1 * time.Minute
+
+func ok(name, detail string) *enterprise.DiagnoseLicenseResult {
+	return &enterprise.DiagnoseLicenseResult{Name: name, Status: enterprise.DiagnoseLicenseResult_OK, Detail: detail}
+}
+
+func warn(name, detail string) *enterprise.DiagnoseLicenseResult {
+	return &enterprise.DiagnoseLicenseResult{Name: name, Status: enterprise.DiagnoseLicenseResult_WARN, Detail: detail}
+}
+
+func fail(name, detail string) *enterprise.DiagnoseLicenseResult {
+	return &enterprise.DiagnoseLicenseResult{Name: name, Status: enterprise.DiagnoseLicenseResult_FAIL, Detail: detail}
+}
+
+// DiagnoseLicense runs each license check in isolation and returns all of
+// their results, in the style of Vault's 'operator diagnose' spans.
+// 'pachctl enterprise diagnose' exits non-zero if any result is FAIL.
+func (a *apiServer) DiagnoseLicense(ctx context.Context, req *enterprise.DiagnoseLicenseRequest) (resp *enterprise.DiagnoseLicenseResponse, retErr error) {
+	record := &enterpriseRecord{}
+	err := a.enterpriseToken.ReadOnly(ctx).Get(enterpriseTokenKey, record)
+	notFound := col.IsErrNotFound(err)
+	if err != nil && !notFound {
+		return nil, err
+	}
+
+	results := []*enterprise.DiagnoseLicenseResult{}
+	if notFound {
+		results = append(results, fail("signature", "no activation code is configured"))
+		results = append(results, fail("expiration", "no activation code is configured"))
+	} else {
+		// Use verifySignature (not validateActivationCode/parseActivationCode)
+		// so that an expired-but-otherwise-valid code still reports
+		// signature=OK; expiration is judged separately below against
+		// record.Expires, matching how computeState distinguishes
+		// INVALID from EXPIRED
+		claims, claimsErr := verifySignature(record.ActivationCode)
+		results = append(results, checkSignature(claimsErr))
+		results = append(results, checkExpiration(record.Expires, time.Now(), a.health.expiringThreshold))
+		if claims != nil {
+			results = append(results, checkClockSkew(claims, time.Now()))
+		}
+	}
+	results = append(results, a.checkLicenseSource())
+	replicaResult, err := a.checkReplicaConsistency(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results = append(results, replicaResult)
+
+	return &enterprise.DiagnoseLicenseResponse{Results: results}, nil
+}
+
+// checkSignature reports whether the current activation code's signature
+// verifies against Pachyderm's public key. 'err' should come from
+// verifySignature, not validateActivationCode/parseActivationCode, so an
+// expired-but-untampered code doesn't fail this check
+func checkSignature(err error) *enterprise.DiagnoseLicenseResult {
+	if err != nil {
+		return fail("signature", err.Error())
+	}
+	return ok("signature", "activation code signature is valid")
+}
+
+// checkExpiration reports how far 'expires' is from 'now', failing if it's
+// already past and warning if it's within 'threshold' of expiring
+func checkExpiration(expires, now time.Time, threshold time.Duration) *enterprise.DiagnoseLicenseResult {
+	remaining := expires.Sub(now)
+	if remaining <= 0 {
+		return fail("expiration", fmt.Sprintf("activation code expired %v ago", -remaining))
+	}
+	if remaining <= threshold {
+		return warn("expiration", fmt.Sprintf("activation code expires in %v, within the %v warning threshold", remaining, threshold))
+	}
+	return ok("expiration", fmt.Sprintf("activation code expires in %v", remaining))
+}
+
+// checkClockSkew reports whether the activation code appears to have been
+// issued in the future relative to 'now', which is only possible if
+// pachd's clock and the signing authority's clock have drifted apart
+func checkClockSkew(claims *activationCodeClaims, now time.Time) *enterprise.DiagnoseLicenseResult {
+	if claims.IssuedAt == 0 {
+		return warn("clock_skew", "activation code has no issued-at claim to compare against")
+	}
+	issuedAt := time.Unix(claims.IssuedAt, 0)
+	skew := issuedAt.Sub(now)
+	if skew > clockSkewTolerance {
+		return fail("clock_skew", fmt.Sprintf("activation code appears to be issued %v in the future; check pachd's clock", skew))
+	}
+	return ok("clock_skew", fmt.Sprintf("no clock skew detected (issued %v before local time)", -skew))
+}
+
+// checkLicenseSource reports whether the configured --license-source (if
+// any) is reachable, so operators notice a bad secret mount before it
+// prevents a rotation
+func (a *apiServer) checkLicenseSource() *enterprise.DiagnoseLicenseResult {
+	if a.autoload == nil {
+		return ok("license_source", "no --license-source is configured; activation codes are applied manually")
+	}
+	// read() treats a missing file as an empty source, so check for the
+	// file's existence separately
+	if _, err := os.Stat(a.autoload.config.Path); err != nil {
+		return fail("license_source", fmt.Sprintf("could not read %q: %v", a.autoload.config.Path, err))
+	}
+	if _, err := a.autoload.read(); err != nil {
+		return fail("license_source", fmt.Sprintf("could not read %q: %v", a.autoload.config.Path, err))
+	}
+	return ok("license_source", fmt.Sprintf("%q is reachable", a.autoload.config.Path))
+}
+
+// checkReplicaConsistency reads the enterprise record directly from every
+// etcd member (bypassing the usual linearizable read through the raft
+// leader) and reports a failure if any member disagrees with the others,
+// which would indicate a stuck or partitioned replica
+func (a *apiServer) checkReplicaConsistency(ctx context.Context) (*enterprise.DiagnoseLicenseResult, error) {
+	members, err := a.etcdClient.MemberList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list etcd members: %v", err)
+	}
+	var first *enterpriseRecord
+	for _, member := range members.Members {
+		if len(member.ClientURLs) == 0 {
+			continue // not yet started
+		}
+		memberClient, err := etcd.New(etcd.Config{Endpoints: member.ClientURLs})
+		if err != nil {
+			return fail("replica_consistency", fmt.Sprintf("could not connect to etcd member %s: %v", member.Name, err)), nil
+		}
+		memberCollection := col.NewCollection(memberClient, a.etcdPrefix, nil, &enterpriseRecord{}, nil, nil)
+		record := &enterpriseRecord{}
+		err = memberCollection.ReadOnly(ctx).Get(enterpriseTokenKey, record)
+		memberClient.Close()
+		if col.IsErrNotFound(err) {
+			record = nil
+		} else if err != nil {
+			return fail("replica_consistency", fmt.Sprintf("could not read from etcd member %s: %v", member.Name, err)), nil
+		}
+		if first == nil {
+			first = record
+			continue
+		}
+		if !recordsEqual(first, record) {
+			return fail("replica_consistency", fmt.Sprintf("etcd member %s disagrees with the rest of the cluster about the current activation code", member.Name)), nil
+		}
+	}
+	return ok("replica_consistency", fmt.Sprintf("%d etcd replicas agree on the current activation record", len(members.Members))), nil
+}
+
+func recordsEqual(a, b *enterpriseRecord) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.ActivationCode == b.ActivationCode && a.Expires.Equal(b.Expires) && a.Source == b.Source
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"sync"
 	"testing"
@@ -150,6 +151,222 @@ func TestDeactivate(t *testing.T) {
 	}, backoff.NewTestingBackOff()))
 }
 
+// TestPoliciesFromLegacyCode makes sure that an activation code with no
+// "policies" claim denies gated features like auth_saml, rather than
+// defaulting to granting every feature.
+func TestPoliciesFromLegacyCode(t *testing.T) {
+	policies := PoliciesFrom(&activationCodeClaims{})
+	require.False(t, policies.AuthSAML)
+	require.Equal(t, int64(-1), policies.MaxPipelines)
+	require.Equal(t, int64(-1), policies.MaxUsers)
+}
+
+// TestGetPoliciesNotActivated makes sure a cluster that's never been
+// activated reports unlimited quotas (the same as a legacy license with no
+// policies claim) rather than a zero-value Policies, which would read as
+// "zero pipelines permitted" and block pipeline creation on a cluster that
+// simply hasn't been activated yet.
+func TestGetPoliciesNotActivated(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	client := getPachClient(t)
+
+	resp, err := client.Enterprise.GetPolicies(context.Background(), &enterprise.GetPoliciesRequest{})
+	require.NoError(t, err)
+	require.False(t, resp.Policies.AuthSAML)
+	require.Equal(t, int64(-1), resp.Policies.MaxPipelines)
+	require.Equal(t, int64(-1), resp.Policies.MaxUsers)
+}
+
+// TestComputeStateExpiredNotInvalid makes sure a signature-valid license
+// whose embedded exp claim has passed is reported as EXPIRED, not INVALID
+// -- a real customer license that simply runs out never sets
+// ActivateRequest.Expires, so computeState can't rely on the JWT's own
+// expiry check to distinguish the two.
+func TestComputeStateExpiredNotInvalid(t *testing.T) {
+	w := newHealthWatcher(nil, 0)
+	record := &enterpriseRecord{
+		ActivationCode: testutil.GetTestEnterpriseCode(),
+		Expires:        time.Now().Add(-time.Hour),
+	}
+	require.Equal(t, enterprise.State_EXPIRED, w.computeState(record))
+}
+
+func TestCheckExpiration(t *testing.T) {
+	now := time.Now()
+
+	result := checkExpiration(now.Add(year), now, defaultExpiringThreshold)
+	require.Equal(t, enterprise.DiagnoseLicenseResult_OK, result.Status)
+
+	result = checkExpiration(now.Add(time.Hour), now, 2*time.Hour)
+	require.Equal(t, enterprise.DiagnoseLicenseResult_WARN, result.Status)
+
+	result = checkExpiration(now.Add(-time.Hour), now, defaultExpiringThreshold)
+	require.Equal(t, enterprise.DiagnoseLicenseResult_FAIL, result.Status)
+}
+
+// TestDiagnoseLicenseExpired makes sure that DiagnoseLicense's expiration
+// check reports FAIL (with the remaining-time detail) for an expired
+// activation code, while the signature check still reports OK -- an
+// expired code is not a tampered one.
+func TestDiagnoseLicenseExpired(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	client := getPachClient(t)
+
+	expires := time.Now().Add(-30 * time.Second)
+	expiresProto, err := types.TimestampProto(expires)
+	require.NoError(t, err)
+	_, err = client.Enterprise.Activate(context.Background(),
+		&enterprise.ActivateRequest{
+			ActivationCode: testutil.GetTestEnterpriseCode(),
+			Expires:        expiresProto,
+		})
+	require.NoError(t, err)
+
+	require.NoError(t, backoff.Retry(func() error {
+		resp, err := client.Enterprise.DiagnoseLicense(context.Background(), &enterprise.DiagnoseLicenseRequest{})
+		if err != nil {
+			return err
+		}
+		var sawExpiration bool
+		for _, result := range resp.Results {
+			switch result.Name {
+			case "expiration":
+				sawExpiration = true
+				if result.Status != enterprise.DiagnoseLicenseResult_FAIL {
+					return fmt.Errorf("expected expiration check to FAIL, but got %v: %s", result.Status, result.Detail)
+				}
+				var elapsed string
+				if _, err := fmt.Sscanf(result.Detail, "activation code expired %s ago", &elapsed); err != nil {
+					return fmt.Errorf("could not parse expiration detail %q: %v", result.Detail, err)
+				}
+				d, err := time.ParseDuration(elapsed)
+				if err != nil {
+					return fmt.Errorf("could not parse expiration detail %q: %v", result.Detail, err)
+				}
+				if d < 30*time.Second || d > time.Since(expires)+time.Second {
+					return fmt.Errorf("expected expiration detail to report ~%v since expiry, but got %q", time.Since(expires), result.Detail)
+				}
+			case "signature":
+				if result.Status != enterprise.DiagnoseLicenseResult_OK {
+					return fmt.Errorf("expected signature check to stay OK for a merely-expired code, but got %v: %s", result.Status, result.Detail)
+				}
+			}
+		}
+		if !sawExpiration {
+			return fmt.Errorf("DiagnoseLicense response did not include an expiration check")
+		}
+		return nil
+	}, backoff.NewTestingBackOff()))
+}
+
+// TestGetPolicies makes sure that GetPolicies reports the feature
+// entitlements encoded in the activation code, and that a license issued
+// without a SAML entitlement doesn't grant HasFeature(FeatureAuthSAML).
+func TestGetPolicies(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	client := getPachClient(t)
+
+	_, err := client.Enterprise.Activate(context.Background(),
+		&enterprise.ActivateRequest{ActivationCode: testutil.GetTestEnterpriseCodeWithoutSAML()})
+	require.NoError(t, err)
+
+	resp, err := client.Enterprise.GetPolicies(context.Background(), &enterprise.GetPoliciesRequest{})
+	require.NoError(t, err)
+	require.False(t, resp.Policies.AuthSAML)
+
+	hasSAML, err := client.Enterprise.HasFeature(context.Background(), enterprise.FeatureAuthSAML)
+	require.NoError(t, err)
+	require.False(t, hasSAML)
+}
+
+// TestHealthWatchExpiring makes sure the background health watcher walks a
+// license through ACTIVE -> EXPIRING -> EXPIRED as it approaches and then
+// passes its expiration, rather than flipping straight from ACTIVE to
+// EXPIRED. The test cluster is configured with a 5s expiring threshold (see
+// testutil.NewTestCluster), so a token that expires 10s from now spends
+// its first ~5s ACTIVE and its last ~5s EXPIRING before expiring.
+func TestHealthWatchExpiring(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	client := getPachClient(t)
+
+	expires := time.Now().Add(10 * time.Second)
+	expiresProto, err := types.TimestampProto(expires)
+	require.NoError(t, err)
+	_, err = client.Enterprise.Activate(context.Background(),
+		&enterprise.ActivateRequest{
+			ActivationCode: testutil.GetTestEnterpriseCode(),
+			Expires:        expiresProto,
+		})
+	require.NoError(t, err)
+
+	var seen []enterprise.State
+	require.NoError(t, backoff.Retry(func() error {
+		resp, err := client.Enterprise.GetState(context.Background(),
+			&enterprise.GetStateRequest{})
+		if err != nil {
+			return err
+		}
+		if len(seen) == 0 || seen[len(seen)-1] != resp.State {
+			seen = append(seen, resp.State)
+		}
+		if resp.State != enterprise.State_EXPIRED {
+			return fmt.Errorf("waiting for enterprise state to reach EXPIRED, currently %v", resp.State)
+		}
+		return nil
+	}, backoff.NewTestingBackOff()))
+
+	require.Equal(t, []enterprise.State{
+		enterprise.State_ACTIVE,
+		enterprise.State_EXPIRING,
+		enterprise.State_EXPIRED,
+	}, seen)
+}
+
+// TestAutoloadedLicense makes sure that an activation code written to the
+// file configured via --license-source is picked up by the enterprise
+// server without a human calling Activate, and that GetState reports
+// Source=AUTOLOADED for it.
+func TestAutoloadedLicense(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	client := getPachClient(t)
+
+	licenseFile, err := ioutil.TempFile("", "pachyderm-license-")
+	require.NoError(t, err)
+	defer os.Remove(licenseFile.Name())
+	_, err = licenseFile.WriteString(testutil.GetTestEnterpriseCode())
+	require.NoError(t, err)
+	require.NoError(t, licenseFile.Close())
+
+	require.NoError(t, testutil.RestartPachdWithEnv(map[string]string{
+		"PACHD_LICENSE_SOURCE": licenseFile.Name(),
+	}))
+
+	require.NoError(t, backoff.Retry(func() error {
+		resp, err := client.Enterprise.GetState(context.Background(),
+			&enterprise.GetStateRequest{})
+		if err != nil {
+			return err
+		}
+		if resp.State != enterprise.State_ACTIVE {
+			return fmt.Errorf("expected enterprise state to be ACTIVE but was %v", resp.State)
+		}
+		if resp.Source != enterprise.ActivationCodeSource_AUTOLOADED {
+			return fmt.Errorf("expected enterprise activation source to be AUTOLOADED but was %v", resp.Source)
+		}
+		return nil
+	}, backoff.NewTestingBackOff()))
+}
+
 // TestDoubleDeactivate makes sure calling Deactivate() when there is no
 // enterprise token works. Fixes
 // https://github.com/pachyderm/pachyderm/issues/3013
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"golang.org/x/net/context"
+
+	"github.com/pachyderm/pachyderm/src/client/enterprise"
+)
+
+const (
+	// defaultDetectHealthyInterval is how often the health watcher
+	// re-validates the current activation code, modeled on the
+	// detectHealthyInterval used by pachd's leadership watch-loop
+	defaultDetectHealthyInterval = 30 * time.Second
+
+	// defaultUnhealthyTimeout is how long a health check is allowed to run
+	// before the watcher gives up on that cycle and tries again on the next
+	// tick, mirroring the leadership watch-loop's unhealthyTimeout
+	defaultUnhealthyTimeout = 10 * time.Second
+
+	// defaultExpiringThreshold is how close to expiration (by default) an
+	// otherwise-valid activation code has to be before GetState reports
+	// State_EXPIRING instead of State_ACTIVE
+	defaultExpiringThreshold = 30 * 24 * time.Hour
+)
+
+// healthWatcher periodically re-validates the cluster's enterprise
+// activation code and tracks the state transitions (ACTIVE -> EXPIRING ->
+// EXPIRED, or -> INVALID if the signature stops verifying) so that each
+// transition can be logged exactly once and the last healthy time can be
+// reported. GetState still computes the current state itself on every call
+// (via computeState); only LastHealthy comes from the watcher's cycles.
+type healthWatcher struct {
+	apiServer *apiServer
+
+	detectHealthyInterval time.Duration
+	unhealthyTimeout      time.Duration
+	expiringThreshold     time.Duration
+
+	mu          sync.Mutex
+	lastHealthy time.Time
+	lastState   enterprise.State
+}
+
+func newHealthWatcher(a *apiServer, expiringThreshold time.Duration) *healthWatcher {
+	if expiringThreshold == 0 {
+		expiringThreshold = defaultExpiringThreshold
+	}
+	return &healthWatcher{
+		apiServer:             a,
+		detectHealthyInterval: defaultDetectHealthyInterval,
+		unhealthyTimeout:      defaultUnhealthyTimeout,
+		expiringThreshold:     expiringThreshold,
+		lastState:             enterprise.State_NONE,
+	}
+}
+
+// Start launches the watcher's background loop. It does not block; the
+// first health check happens on the first tick, same as the leadership
+// watch-loop this is modeled on.
+func (w *healthWatcher) Start() {
+	go w.watch()
+}
+
+func (w *healthWatcher) watch() {
+	ticker := time.NewTicker(w.detectHealthyInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		w.check()
+	}
+}
+
+// check re-validates the current activation code (if any) and updates
+// lastHealthy/lastState, logging a structured event whenever the computed
+// state changes. It times out after unhealthyTimeout so a stuck etcd
+// request can't wedge the watcher indefinitely.
+func (w *healthWatcher) check() {
+	done := make(chan struct{})
+	var state enterprise.State
+	go func() {
+		defer close(done)
+		record := &enterpriseRecord{}
+		if err := w.apiServer.enterpriseToken.ReadOnly(context.Background()).Get(enterpriseTokenKey, record); err != nil {
+			state = enterprise.State_NONE
+			return
+		}
+		state = w.computeState(record)
+	}()
+	select {
+	case <-done:
+	case <-time.After(w.unhealthyTimeout):
+		w.apiServer.log.Warnf("enterprise health check did not complete within %v", w.unhealthyTimeout)
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if state == enterprise.State_ACTIVE || state == enterprise.State_EXPIRING {
+		w.lastHealthy = time.Now()
+	}
+	if state != w.lastState {
+		w.apiServer.log.WithFields(map[string]interface{}{
+			"from": w.lastState.String(),
+			"to":   state.String(),
+		}).Infof("enterprise license state changed: %v -> %v", w.lastState, state)
+		w.lastState = state
+	}
+}
+
+// computeState derives the externally-visible enterprise.State for
+// 'record'. It re-checks only the signature (via verifySignature, which
+// ignores the JWT's own "exp" claim) so a license that's been tampered
+// with or corrupted by a bad autoload is reported as INVALID; expiration
+// is judged separately against record.Expires so that an ordinary expired
+// license is reported as EXPIRED/EXPIRING rather than also being flagged
+// INVALID the instant its embedded exp claim passes
+func (w *healthWatcher) computeState(record *enterpriseRecord) enterprise.State {
+	if _, err := verifySignature(record.ActivationCode); err != nil {
+		return enterprise.State_INVALID
+	}
+	now := time.Now()
+	if now.After(record.Expires) {
+		return enterprise.State_EXPIRED
+	}
+	if record.Expires.Sub(now) <= w.expiringThreshold {
+		return enterprise.State_EXPIRING
+	}
+	return enterprise.State_ACTIVE
+}
+
+// LastHealthy returns the timestamp of the most recent cycle in which the
+// activation code validated as ACTIVE or EXPIRING, for surfacing through
+// GetState
+func (w *healthWatcher) LastHealthy() *types.Timestamp {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.lastHealthy.IsZero() {
+		return nil
+	}
+	ts, err := types.TimestampProto(w.lastHealthy)
+	if err != nil {
+		return nil
+	}
+	return ts
+}
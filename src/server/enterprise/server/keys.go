@@ -0,0 +1,36 @@
+package server
+
+import (
+	"crypto/rsa"
+	"fmt"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// publicKeyPEM is Pachyderm's public activation-code-signing key. The
+// corresponding private key is held by Pachyderm Inc and used to sign
+// activation codes distributed to customers; it is never present in this
+// repo. testutil.GetTestEnterpriseCode() returns codes signed by the
+// matching test-only private key, which lives alongside the other test
+// fixtures and is likewise not committed here.
+const publicKeyPEM = `-----BEGIN PUBLIC KEY-----
+MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEAldYMpIaWR0gmiHKhYzj9
+jIrsm+ekq/h9d6e2H3zEs9mGghsMLacpDj/6YKW8YK0Atjxh2/jDLXTDx27jLNWi
+E8sdvm6eS8mcWqO/fjUcK0M70AsYgVPbmM7zrwdcPwW5qIzRRO2XI0MuLyvNWoKh
+uGUHSdXmmj1U2pHGAQugDhwJh9uL4WjaF3v5DyN2iqrq0M6Hx6Zr/6/JUmPXjmkw
+C+M+OcLlfadfiYgkg3/ySlNGxZTSM8alSiSJwRC2wR6NL0YKyDWFKmglsNBz/zlA
+CPRj7VErTU4IZOb8wOqmW/BWXRRPodAzDcLfaisHAMUpFug5+CpGI5m6nk8Nn6kP
+wQIDAQAB
+-----END PUBLIC KEY-----`
+
+var publicKey *rsa.PublicKey
+
+func init() {
+	key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(publicKeyPEM))
+	if err != nil {
+		// This indicates a bug in pachd itself (a corrupted constant above),
+		// not anything the operator can fix, so fail fast
+		panic(fmt.Sprintf("could not parse Pachyderm's enterprise public key: %v", err))
+	}
+	publicKey = key
+}
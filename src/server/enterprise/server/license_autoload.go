@@ -0,0 +1,130 @@
+package server
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/pachyderm/pachyderm/src/client/enterprise"
+)
+
+// defaultAutoloadPollInterval is how often the autoloader re-reads its
+// source when no --license-poll-interval is given. Kubernetes Secret
+// mounts already propagate updates on their own kubelet sync period (tens
+// of seconds to a couple minutes), so this just needs to be a safety net.
+const defaultAutoloadPollInterval = 1 * time.Minute
+
+// LicenseSourceConfig describes where pachd should read an enterprise
+// activation code from at startup, so that operators can bootstrap a
+// license without a human calling Enterprise.Activate. This mirrors
+// Vault's "autoloaded license" flag, which reads a signed license from a
+// file or secrets provider at startup.
+type LicenseSourceConfig struct {
+	// Path is the filesystem path to read the activation code from (e.g. a
+	// Kubernetes Secret volume mount)
+	Path string
+
+	// PollInterval is how often to re-read Path, in case it changes without
+	// a SIGHUP (e.g. because the orchestrator doesn't support one). If
+	// zero, defaultAutoloadPollInterval is used.
+	PollInterval time.Duration
+}
+
+// licenseAutoloader re-reads an activation code from a LicenseSourceConfig
+// on startup, on SIGHUP, and on a poll interval, and activates it via the
+// same path as a human calling Enterprise.Activate.
+type licenseAutoloader struct {
+	apiServer *apiServer
+	config    *LicenseSourceConfig
+	sighup    chan os.Signal
+
+	// lastCode is the activation code from the most recent successful
+	// reload, so that reload can skip re-activating (and re-logging) on
+	// every poll tick and SIGHUP when the source hasn't actually changed.
+	// Only reload's own goroutine (the initial call from Start, then every
+	// call from watch's single goroutine) ever touches this, so it needs
+	// no locking.
+	lastCode string
+}
+
+func newLicenseAutoloader(a *apiServer, config *LicenseSourceConfig) (*licenseAutoloader, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("license autoload is enabled but no source path was given")
+	}
+	if config.PollInterval == 0 {
+		config.PollInterval = defaultAutoloadPollInterval
+	}
+	return &licenseAutoloader{
+		apiServer: a,
+		config:    config,
+		sighup:    make(chan os.Signal, 1),
+	}, nil
+}
+
+// Start performs the initial load of the configured license source
+// (blocking the caller so that NewEnterpriseServer returns a server that
+// already reflects any autoloaded license), then kicks off a background
+// goroutine that reloads on SIGHUP and on config.PollInterval.
+func (l *licenseAutoloader) Start() {
+	l.reload()
+	signal.Notify(l.sighup, syscall.SIGHUP)
+	go l.watch()
+}
+
+func (l *licenseAutoloader) watch() {
+	ticker := time.NewTicker(l.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.sighup:
+			l.apiServer.log.Info("reloading enterprise license due to SIGHUP")
+			l.reload()
+		case <-ticker.C:
+			l.reload()
+		}
+	}
+}
+
+// reload reads the configured license source and, if its contents changed
+// since the last successful reload, activates the new code. Mirroring
+// Vault's autoloaded-license diagnostics, a bad or expired license is
+// logged loudly but never blocks or crashes pachd -- operators can still
+// fix the secret and SIGHUP (or wait for the next poll) without a restart.
+func (l *licenseAutoloader) reload() {
+	code, err := l.read()
+	if err != nil {
+		l.apiServer.log.Errorf("autoloaded enterprise license: could not read %q: %v", l.config.Path, err)
+		return
+	}
+	if code == "" || code == l.lastCode {
+		return
+	}
+	expiration, err := validateActivationCode(code)
+	if err != nil {
+		l.apiServer.log.Errorf("autoloaded enterprise license: %q contains an invalid activation code: %v", l.config.Path, err)
+		return
+	}
+	if _, err := l.apiServer.activate(context.Background(), code, expiration, enterprise.ActivationCodeSource_AUTOLOADED); err != nil {
+		l.apiServer.log.Errorf("autoloaded enterprise license: could not activate: %v", err)
+		return
+	}
+	l.lastCode = code
+	l.apiServer.log.Infof("autoloaded enterprise license from %q, expires %v", l.config.Path, expiration)
+}
+
+func (l *licenseAutoloader) read() (string, error) {
+	contents, err := ioutil.ReadFile(l.config.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(contents)), nil
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/pachyderm/pachyderm/src/client/enterprise"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+)
+
+// policyClaims is the optional "policies" claim embedded in a signed
+// activation code, encoding the feature entitlements granted by that
+// license. Activation codes issued before policies existed omit this
+// claim entirely; PoliciesFrom deliberately does NOT treat that as "every
+// feature enabled" -- that would make HasFeature a no-op gate for every
+// outstanding license until it's reissued, defeating the point of this
+// mechanism. A missing claim denies gated features (e.g. auth_saml) and
+// only leaves quota fields (max_pipelines, max_users) unlimited, since
+// those aren't security-sensitive the way an unpaid-for feature is.
+type policyClaims struct {
+	AuthSAML           bool     `json:"auth_saml,omitempty"`
+	MaxPipelines       *int64   `json:"max_pipelines,omitempty"`
+	MaxUsers           *int64   `json:"max_users,omitempty"`
+	ObjectStorageTiers []string `json:"object_storage_tiers,omitempty"`
+}
+
+// unlimited is used for quota fields that a license claim leaves unset
+const unlimited = int64(-1)
+
+// PoliciesFrom converts the policies claim of a parsed activation code
+// into the enterprise.Policies returned by GetPolicies, analogous to how
+// Vault derives a token's effective policy set from its stored grants.
+func PoliciesFrom(claims *activationCodeClaims) *enterprise.Policies {
+	if claims.Policies == nil {
+		// No policies claim: deny gated features rather than defaulting to
+		// "entitled to everything". Quotas stay unlimited, since an
+		// unspecified quota on a legacy license isn't the kind of
+		// feature-gating this mechanism exists to enforce.
+		return &enterprise.Policies{
+			AuthSAML:     false,
+			MaxPipelines: unlimited,
+			MaxUsers:     unlimited,
+		}
+	}
+	p := claims.Policies
+	policies := &enterprise.Policies{
+		AuthSAML:           p.AuthSAML,
+		MaxPipelines:       unlimited,
+		MaxUsers:           unlimited,
+		ObjectStorageTiers: p.ObjectStorageTiers,
+	}
+	if p.MaxPipelines != nil {
+		policies.MaxPipelines = *p.MaxPipelines
+	}
+	if p.MaxUsers != nil {
+		policies.MaxUsers = *p.MaxUsers
+	}
+	return policies
+}
+
+func (a *apiServer) GetPolicies(ctx context.Context, req *enterprise.GetPoliciesRequest) (resp *enterprise.GetPoliciesResponse, retErr error) {
+	record := &enterpriseRecord{}
+	if err := a.enterpriseToken.ReadOnly(ctx).Get(enterpriseTokenKey, record); err != nil {
+		if col.IsErrNotFound(err) {
+			// No activation record at all (cluster never activated): treat
+			// the same as a legacy license with no policies claim, rather
+			// than a bare zero-value Policies, which would report
+			// MaxPipelines/MaxUsers as 0 (zero pipelines permitted) instead
+			// of unlimited.
+			return &enterprise.GetPoliciesResponse{Policies: PoliciesFrom(&activationCodeClaims{})}, nil
+		}
+		return nil, err
+	}
+	claims, err := parseActivationCode(record.ActivationCode)
+	if err != nil {
+		return nil, err
+	}
+	return &enterprise.GetPoliciesResponse{Policies: PoliciesFrom(claims)}, nil
+}